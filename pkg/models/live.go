@@ -3,6 +3,7 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -18,6 +19,18 @@ type ChannelClientCount func(orgID int64, channel string) (int, error)
 type SubscribeEvent struct {
 	Channel string
 	Path    string
+	// Since, if set, means the client is reconnecting and asking to recover
+	// messages published after this point. A handler backed by a
+	// LiveHistoryStore can use it to populate SubscribeReply.Data and set
+	// SubscribeReply.Recover=true.
+	Since *RecoverSince
+}
+
+// RecoverSince describes the point a reconnecting client last saw in a
+// channel's history, as either a sequence number or a timestamp.
+type RecoverSince struct {
+	Seq  uint64
+	Time time.Time
 }
 
 // SubscribeReply is a reaction to SubscribeEvent.
@@ -99,6 +112,13 @@ type GetLiveMessageQuery struct {
 	Channel string
 }
 
+// RemoteWriteProtocolVersion10 is the original Prometheus Remote-Write protocol.
+const RemoteWriteProtocolVersion10 = "1.0"
+
+// RemoteWriteProtocolVersion20 is the Prometheus Remote-Write 2.0 protocol, which
+// carries an interned symbol table and metadata inline with each time series.
+const RemoteWriteProtocolVersion20 = "2.0"
+
 // LiveChannelPlainConfig contains various channel configuration options.
 type LiveChannelPlainConfig struct {
 	// RemoteWriteEnabled to enable remote write for a channel.
@@ -108,6 +128,18 @@ type LiveChannelPlainConfig struct {
 	// RemoteWriteSampleMilliseconds allow setting minimal time before
 	// different remote writes for a channel. 0 means no sampling interval.
 	RemoteWriteSampleMilliseconds int64 `json:"remoteWriteSampleMilliseconds,omitempty"`
+	// RemoteWriteProtocolVersion sets the wire protocol used when sending
+	// samples to RemoteWriteEndpoint. One of "1.0" (default) or "2.0". See
+	// RemoteWriteProtocolVersion10/RemoteWriteProtocolVersion20.
+	RemoteWriteProtocolVersion string `json:"remoteWriteProtocolVersion,omitempty"`
+	// TLSServerName overrides the server name used to verify the remote
+	// write endpoint's certificate, e.g. when RemoteWriteEndpoint is an IP.
+	TLSServerName string `json:"tlsServerName,omitempty"`
+	// TLSInsecureSkipVerify disables verification of the remote write
+	// endpoint's certificate chain. Not recommended outside of testing.
+	TLSInsecureSkipVerify bool `json:"tlsInsecureSkipVerify,omitempty"`
+	// ProxyURL is an optional HTTP(S) proxy to use for remote write requests.
+	ProxyURL string `json:"proxyUrl,omitempty"`
 }
 
 // LiveChannelSecureConfig contains various channel configuration options
@@ -117,6 +149,44 @@ type LiveChannelSecureConfig struct {
 	RemoteWriteUser string `json:"remoteWriteUser,omitempty"`
 	// RemoteWritePassword is a password/token for remote write request.
 	RemoteWritePassword string `json:"remoteWritePassword,omitempty"`
+	// TLSClientCert is a PEM-encoded client certificate used for mTLS to the
+	// remote write endpoint.
+	TLSClientCert string `json:"tlsClientCert,omitempty"`
+	// TLSClientKey is the PEM-encoded private key matching TLSClientCert.
+	TLSClientKey string `json:"tlsClientKey,omitempty"`
+	// TLSCACert is a PEM-encoded CA bundle used to verify the remote write
+	// endpoint, in place of the system trust store.
+	TLSCACert string `json:"tlsCACert,omitempty"`
+	// BearerToken is a bearer token sent as the remote write Authorization header.
+	BearerToken string `json:"bearerToken,omitempty"`
+	// BearerTokenFile is a path to a file containing the bearer token,
+	// re-read on each reload so it can be rotated without a config change.
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+}
+
+// AuthMethod reports which single remote write auth method a secure config
+// selects ("basic", "mtls" or "bearer"), "" if none is configured, or an
+// error if more than one is configured.
+func (c LiveChannelSecureConfig) AuthMethod() (string, error) {
+	methods := make([]string, 0, 3)
+	if c.RemoteWriteUser != "" || c.RemoteWritePassword != "" {
+		methods = append(methods, "basic")
+	}
+	if c.TLSClientCert != "" || c.TLSClientKey != "" {
+		methods = append(methods, "mtls")
+	}
+	if c.BearerToken != "" || c.BearerTokenFile != "" {
+		methods = append(methods, "bearer")
+	}
+
+	switch len(methods) {
+	case 0:
+		return "", nil
+	case 1:
+		return methods[0], nil
+	default:
+		return "", fmt.Errorf("only one remote write auth method may be configured, found %v", methods)
+	}
 }
 
 // LiveChannel represents channel metadata saved in database.
@@ -140,6 +210,12 @@ type CreateLiveChannelConfigCommand struct {
 	Result *LiveChannelConfig
 }
 
+// Validate checks that Secure selects at most one remote write auth method.
+func (cmd *CreateLiveChannelConfigCommand) Validate() error {
+	_, err := cmd.Secure.AuthMethod()
+	return err
+}
+
 // Also acts as api DTO.
 type UpdateLiveChannelConfigCommand struct {
 	Id      int64                   `json:"id" binding:"Required"`
@@ -151,6 +227,12 @@ type UpdateLiveChannelConfigCommand struct {
 	Result *LiveChannelConfig
 }
 
+// Validate checks that Secure selects at most one remote write auth method.
+func (cmd *UpdateLiveChannelConfigCommand) Validate() error {
+	_, err := cmd.Secure.AuthMethod()
+	return err
+}
+
 // Also acts as api DTO.
 type DeleteLiveChannelConfigCommand struct {
 	Id    int64 `json:"id" binding:"Required"`
@@ -0,0 +1,28 @@
+// Package history implements pluggable storage for Grafana Live channel
+// history, backing the replay requested via models.SubscribeReply.Recover.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// LiveHistoryStore persists recent messages published to a Live channel so
+// a reconnecting client can recover what it missed.
+type LiveHistoryStore interface {
+	// Append adds data to channel's history, trimming to the store's
+	// configured retention, and returns the sequence number assigned to it.
+	Append(ctx context.Context, orgID int64, channel string, data json.RawMessage, ttl time.Duration) (seq uint64, err error)
+
+	// Since returns messages published after sinceSeq (or, if sinceSeq is 0,
+	// after sinceTime), oldest first, capped at limit, along with the
+	// latest sequence number known for the channel.
+	Since(ctx context.Context, orgID int64, channel string, sinceSeq uint64, sinceTime time.Time, limit int) ([]models.LiveMessage, uint64, error)
+
+	// Trim drops the oldest entries in channel's history so at most maxSize
+	// remain.
+	Trim(ctx context.Context, orgID int64, channel string, maxSize int) error
+}
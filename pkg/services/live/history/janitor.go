@@ -0,0 +1,58 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	livemetrics "github.com/grafana/grafana/pkg/services/live/metrics"
+)
+
+// Janitor periodically trims channel history down to a configured size,
+// as a backstop for stores (like MemoryStore) whose TTL expiry only runs
+// on read/write of a given channel.
+type Janitor struct {
+	store    LiveHistoryStore
+	interval time.Duration
+	maxSize  int
+	metrics  *livemetrics.LiveHistory
+
+	channels func() []JanitorChannel
+}
+
+// JanitorChannel identifies a channel the Janitor should sweep.
+type JanitorChannel struct {
+	OrgID   int64
+	Channel string
+}
+
+// NewJanitor creates a Janitor that calls channels on every tick to decide
+// what to sweep, then trims each to maxSize via store.
+func NewJanitor(store LiveHistoryStore, interval time.Duration, maxSize int, metrics *livemetrics.LiveHistory, channels func() []JanitorChannel) *Janitor {
+	return &Janitor{store: store, interval: interval, maxSize: maxSize, metrics: metrics, channels: channels}
+}
+
+// Run sweeps channels every interval until ctx is canceled.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.sweep(ctx)
+		}
+	}
+}
+
+func (j *Janitor) sweep(ctx context.Context) {
+	if j.metrics != nil {
+		j.metrics.ActiveJanitors.Inc()
+		defer j.metrics.ActiveJanitors.Dec()
+	}
+
+	for _, c := range j.channels() {
+		_ = j.store.Trim(ctx, c.OrgID, c.Channel, j.maxSize)
+	}
+}
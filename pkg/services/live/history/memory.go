@@ -0,0 +1,140 @@
+package history
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	livemetrics "github.com/grafana/grafana/pkg/services/live/metrics"
+)
+
+type memoryEntry struct {
+	seq       uint64
+	data      json.RawMessage
+	published time.Time
+	expiresAt time.Time
+}
+
+type memoryChannel struct {
+	mu      sync.Mutex
+	entries *list.List // of *memoryEntry, oldest first
+	lastSeq uint64
+}
+
+// MemoryStore is an in-process LiveHistoryStore. History does not survive a
+// restart and is not shared across Grafana instances; use RedisStreamsStore
+// for that.
+type MemoryStore struct {
+	metrics *livemetrics.LiveHistory
+
+	mu       sync.Mutex
+	channels map[string]*memoryChannel
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore(metrics *livemetrics.LiveHistory) *MemoryStore {
+	return &MemoryStore{metrics: metrics, channels: make(map[string]*memoryChannel)}
+}
+
+func (s *MemoryStore) channelFor(orgID int64, channel string) *memoryChannel {
+	key := channelKey(orgID, channel)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.channels[key]
+	if !ok {
+		c = &memoryChannel{entries: list.New()}
+		s.channels[key] = c
+	}
+	return c
+}
+
+func (s *MemoryStore) Append(ctx context.Context, orgID int64, channel string, data json.RawMessage, ttl time.Duration) (uint64, error) {
+	start := time.Now()
+	c := s.channelFor(orgID, channel)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastSeq++
+	entry := &memoryEntry{seq: c.lastSeq, data: data, published: start}
+	if ttl > 0 {
+		entry.expiresAt = start.Add(ttl)
+	}
+	c.entries.PushBack(entry)
+	expireLocked(c, start)
+
+	if s.metrics != nil {
+		s.metrics.PersistDuration.Observe(time.Since(start).Seconds())
+	}
+	return entry.seq, nil
+}
+
+func (s *MemoryStore) Since(ctx context.Context, orgID int64, channel string, sinceSeq uint64, sinceTime time.Time, limit int) ([]models.LiveMessage, uint64, error) {
+	c := s.channelFor(orgID, channel)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expireLocked(c, time.Now())
+
+	var out []models.LiveMessage
+	for e := c.entries.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*memoryEntry)
+		if entry.seq <= sinceSeq {
+			continue
+		}
+		if sinceSeq == 0 && !sinceTime.IsZero() && !entry.published.After(sinceTime) {
+			continue
+		}
+		out = append(out, models.LiveMessage{
+			OrgId:     orgID,
+			Channel:   channel,
+			Data:      entry.data,
+			Published: entry.published,
+		})
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecoverMessagesTotal.Add(float64(len(out)))
+	}
+	return out, c.lastSeq, nil
+}
+
+func (s *MemoryStore) Trim(ctx context.Context, orgID int64, channel string, maxSize int) error {
+	c := s.channelFor(orgID, channel)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.entries.Len() > maxSize {
+		c.entries.Remove(c.entries.Front())
+	}
+	return nil
+}
+
+// expireLocked drops entries whose TTL has passed. Must be called with
+// c.mu held.
+func expireLocked(c *memoryChannel, now time.Time) {
+	for e := c.entries.Front(); e != nil; {
+		entry := e.Value.(*memoryEntry)
+		if entry.expiresAt.IsZero() || entry.expiresAt.After(now) {
+			break
+		}
+		next := e.Next()
+		c.entries.Remove(e)
+		e = next
+	}
+}
+
+func channelKey(orgID int64, channel string) string {
+	return strconv.FormatInt(orgID, 10) + "/" + channel
+}
@@ -0,0 +1,80 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_AppendAndSince(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(nil)
+
+	seq1, err := s.Append(ctx, 1, "a", []byte(`{"v":1}`), 0)
+	require.NoError(t, err)
+	seq2, err := s.Append(ctx, 1, "a", []byte(`{"v":2}`), 0)
+	require.NoError(t, err)
+	assert.Less(t, seq1, seq2)
+
+	msgs, lastSeq, err := s.Since(ctx, 1, "a", 0, time.Time{}, 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, seq2, lastSeq)
+
+	// Resuming from seq1 should only return the message appended after it.
+	msgs, lastSeq, err = s.Since(ctx, 1, "a", seq1, time.Time{}, 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, `{"v":2}`, string(msgs[0].Data))
+	assert.Equal(t, seq2, lastSeq)
+}
+
+func TestMemoryStore_SinceRespectsLimitButReportsLatestSeq(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(nil)
+
+	var lastAppended uint64
+	for i := 0; i < 5; i++ {
+		seq, err := s.Append(ctx, 1, "a", []byte(`{}`), 0)
+		require.NoError(t, err)
+		lastAppended = seq
+	}
+
+	msgs, lastSeq, err := s.Since(ctx, 1, "a", 0, time.Time{}, 2)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	// lastSeq must reflect the channel's true latest sequence, not the tail
+	// of this truncated batch, so a slow-draining client knows it hasn't
+	// caught up yet.
+	assert.Equal(t, lastAppended, lastSeq)
+}
+
+func TestMemoryStore_ChannelsAreIsolatedByOrg(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(nil)
+
+	_, err := s.Append(ctx, 1, "a", []byte(`{}`), 0)
+	require.NoError(t, err)
+
+	msgs, _, err := s.Since(ctx, 2, "a", 0, time.Time{}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+}
+
+func TestMemoryStore_Trim(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(nil)
+
+	for i := 0; i < 5; i++ {
+		_, err := s.Append(ctx, 1, "a", []byte(`{}`), 0)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Trim(ctx, 1, "a", 2))
+
+	msgs, _, err := s.Since(ctx, 1, "a", 0, time.Time{}, 0)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 2)
+}
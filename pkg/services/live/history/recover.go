@@ -0,0 +1,29 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Recover looks up the messages a reconnecting client missed, for use from
+// ChannelHandler.OnSubscribe when e.Since is set. It returns the recovered
+// messages JSON-encoded as a slice (ready for SubscribeReply.Data) and the
+// channel's latest known sequence number.
+func Recover(ctx context.Context, store LiveHistoryStore, orgID int64, channel string, since *models.RecoverSince, limit int) (json.RawMessage, uint64, error) {
+	if since == nil {
+		return nil, 0, nil
+	}
+
+	msgs, lastSeq, err := store.Since(ctx, orgID, channel, since.Seq, since.Time, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := json.Marshal(msgs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, lastSeq, nil
+}
@@ -0,0 +1,158 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/grafana/grafana/pkg/models"
+	livemetrics "github.com/grafana/grafana/pkg/services/live/metrics"
+)
+
+// RedisStreamsStore is a LiveHistoryStore backed by a Redis Stream per
+// channel, so history survives restarts and is shared across a Grafana HA
+// cluster the way Live's other pub/sub state already is.
+type RedisStreamsStore struct {
+	client  redis.UniversalClient
+	prefix  string
+	metrics *livemetrics.LiveHistory
+}
+
+// NewRedisStreamsStore creates a RedisStreamsStore. prefix namespaces the
+// stream keys, e.g. "grafana.live.history".
+func NewRedisStreamsStore(client redis.UniversalClient, prefix string, metrics *livemetrics.LiveHistory) *RedisStreamsStore {
+	return &RedisStreamsStore{client: client, prefix: prefix, metrics: metrics}
+}
+
+func (s *RedisStreamsStore) streamKey(orgID int64, channel string) string {
+	return fmt.Sprintf("%s.%d.%s", s.prefix, orgID, channel)
+}
+
+func (s *RedisStreamsStore) Append(ctx context.Context, orgID int64, channel string, data json.RawMessage, ttl time.Duration) (uint64, error) {
+	start := time.Now()
+	key := s.streamKey(orgID, channel)
+
+	id, err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		Values: map[string]interface{}{"data": []byte(data)},
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("XADD %s: %w", key, err)
+	}
+	if ttl > 0 {
+		s.client.Expire(ctx, key, ttl)
+	}
+
+	if s.metrics != nil {
+		s.metrics.PersistDuration.Observe(time.Since(start).Seconds())
+	}
+	return streamIDToSeq(id)
+}
+
+func (s *RedisStreamsStore) Since(ctx context.Context, orgID int64, channel string, sinceSeq uint64, sinceTime time.Time, limit int) ([]models.LiveMessage, uint64, error) {
+	key := s.streamKey(orgID, channel)
+
+	start := "(" + seqToStreamID(sinceSeq)
+	if sinceSeq == 0 {
+		start = seqToStreamID(uint64(sinceTime.UnixMilli()) * seqPerMilli)
+		if sinceTime.IsZero() {
+			start = "-"
+		}
+	}
+
+	args := &redis.XRangeArgs{Stream: key, Start: start, Stop: "+"}
+	var msgs []redis.XMessage
+	var err error
+	if limit > 0 {
+		msgs, err = s.client.XRangeN(ctx, key, start, "+", int64(limit)).Result()
+	} else {
+		msgs, err = s.client.XRange(ctx, key, args.Start, args.Stop).Result()
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("XRANGE %s: %w", key, err)
+	}
+
+	out := make([]models.LiveMessage, 0, len(msgs))
+	for _, m := range msgs {
+		seq, err := streamIDToSeq(m.ID)
+		if err != nil {
+			continue
+		}
+
+		raw, _ := m.Values["data"].(string)
+		out = append(out, models.LiveMessage{
+			OrgId:     orgID,
+			Channel:   channel,
+			Data:      json.RawMessage(raw),
+			Published: time.UnixMilli(int64(seq / seqPerMilli)),
+		})
+	}
+
+	// lastSeq must reflect the latest sequence number known for the whole
+	// channel, not just the tail of this (possibly limit-truncated) batch,
+	// or a client draining a large backlog slower than it grows would think
+	// it had caught up before it had.
+	var lastSeq uint64
+	if last, err := s.client.XRevRangeN(ctx, key, "+", "-", 1).Result(); err == nil && len(last) > 0 {
+		lastSeq, _ = streamIDToSeq(last[0].ID)
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecoverMessagesTotal.Add(float64(len(out)))
+	}
+	return out, lastSeq, nil
+}
+
+func (s *RedisStreamsStore) Trim(ctx context.Context, orgID int64, channel string, maxSize int) error {
+	key := s.streamKey(orgID, channel)
+	if err := s.client.XTrimMaxLen(ctx, key, int64(maxSize)).Err(); err != nil {
+		return fmt.Errorf("XTRIM %s: %w", key, err)
+	}
+	return nil
+}
+
+// seqPerMilli bounds how many Redis stream entries a single millisecond can
+// hold before streamIDToSeq's packing below collides with the next
+// millisecond. Redis's own per-ms sequence counter essentially never
+// reaches this in practice (it exists to disambiguate same-ms XADDs on one
+// node), so this is generous headroom, not an enforced cap.
+const seqPerMilli = 1_000_000
+
+// Redis stream IDs are "<millis>-<seq>"; Live needs a single monotonically
+// increasing, collision-free counter, so we pack the pair into one uint64
+// as ms*seqPerMilli+seq instead of dropping the seq part, which would make
+// every entry appended in the same millisecond indistinguishable.
+func streamIDToSeq(id string) (uint64, error) {
+	for i := 0; i < len(id); i++ {
+		if id[i] == '-' {
+			ms, err := strconv.ParseUint(id[:i], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			part, err := strconv.ParseUint(id[i+1:], 10, 64)
+			if err != nil {
+				return 0, err
+			}
+			return ms*seqPerMilli + part, nil
+		}
+	}
+	ms, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return ms * seqPerMilli, nil
+}
+
+// seqToStreamID renders seq back as a Redis stream ID for use as an XRANGE
+// bound. It always includes the "-<part>" suffix so exclusive-range queries
+// (the "(" prefix callers add) exclude exactly the entry seq identifies,
+// not every entry sharing its millisecond.
+func seqToStreamID(seq uint64) string {
+	ms := seq / seqPerMilli
+	part := seq % seqPerMilli
+	return strconv.FormatUint(ms, 10) + "-" + strconv.FormatUint(part, 10)
+}
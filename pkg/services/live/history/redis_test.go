@@ -0,0 +1,52 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamIDToSeq_RoundTrip(t *testing.T) {
+	cases := []string{"0-0", "1690000000000-0", "1690000000000-7", "1-999999"}
+	for _, id := range cases {
+		seq, err := streamIDToSeq(id)
+		require.NoError(t, err)
+		assert.Equal(t, id, seqToStreamID(seq))
+	}
+}
+
+func TestStreamIDToSeq_PreservesOrderWithinAMillisecond(t *testing.T) {
+	// Two entries appended in the same millisecond, distinguished only by
+	// Redis's own per-ms sequence counter, must pack to distinct, ordered
+	// uint64s -- collapsing them to the same value would make a
+	// reconnecting client skip or re-receive one.
+	seq1, err := streamIDToSeq("1690000000000-0")
+	require.NoError(t, err)
+	seq2, err := streamIDToSeq("1690000000000-1")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, seq1, seq2)
+	assert.Less(t, seq1, seq2)
+}
+
+func TestSeqToStreamID_ExclusiveRangeExcludesOnlyExactEntry(t *testing.T) {
+	// Since's exclusive range bound is built as "(" + seqToStreamID(sinceSeq);
+	// that must exclude exactly the entry sinceSeq identifies, not every
+	// entry sharing its millisecond.
+	seq, err := streamIDToSeq("1690000000000-0")
+	require.NoError(t, err)
+	assert.Equal(t, "1690000000000-0", seqToStreamID(seq))
+
+	nextSeq, err := streamIDToSeq("1690000000000-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, seqToStreamID(seq), seqToStreamID(nextSeq))
+}
+
+func TestStreamIDToSeq_BareMillisecondID(t *testing.T) {
+	// Redis also accepts a bare millisecond ID, without the "-<seq>" suffix;
+	// streamIDToSeq treats it as sequence 0 of that millisecond.
+	seq, err := streamIDToSeq("1690000000000")
+	require.NoError(t, err)
+	assert.Equal(t, "1690000000000-0", seqToStreamID(seq))
+}
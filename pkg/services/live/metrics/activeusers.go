@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// activeUsersWindow is how far back activeUserTracker reports distinct users.
+const activeUsersWindow = time.Hour
+
+// activeUsersBuckets is the number of one-minute buckets used to cover
+// activeUsersWindow.
+const activeUsersBuckets = int(activeUsersWindow / time.Minute)
+
+// activeUserTracker tracks which users have been seen recently, bucketed by
+// minute in a ring so memory is bounded by (distinct users per minute) *
+// activeUsersBuckets rather than growing with total traffic over time.
+type activeUserTracker struct {
+	mu         sync.Mutex
+	buckets    [activeUsersBuckets]map[int64]struct{}
+	lastMinute int64
+}
+
+func newActiveUserTracker() *activeUserTracker {
+	t := &activeUserTracker{}
+	for i := range t.buckets {
+		t.buckets[i] = make(map[int64]struct{})
+	}
+	return t
+}
+
+func (t *activeUserTracker) track(userID int64, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	minute := now.Unix() / int64(time.Minute/time.Second)
+	t.rotate(minute)
+	t.buckets[minute%int64(activeUsersBuckets)][userID] = struct{}{}
+}
+
+// count returns the number of distinct users seen within activeUsersWindow
+// of now. The caller is responsible for any desired caching/rate limiting;
+// this recomputes the union of all live buckets on every call.
+func (t *activeUserTracker) count(now time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	minute := now.Unix() / int64(time.Minute/time.Second)
+	t.rotate(minute)
+
+	seen := make(map[int64]struct{})
+	for _, bucket := range t.buckets {
+		for userID := range bucket {
+			seen[userID] = struct{}{}
+		}
+	}
+	return len(seen)
+}
+
+// rotate clears buckets that have aged out of the window since lastMinute.
+// Must be called with t.mu held.
+func (t *activeUserTracker) rotate(minute int64) {
+	if t.lastMinute == 0 {
+		t.lastMinute = minute
+		return
+	}
+	age := minute - t.lastMinute
+	if age <= 0 {
+		return
+	}
+	if age > int64(activeUsersBuckets) {
+		age = int64(activeUsersBuckets)
+	}
+	for i := int64(1); i <= age; i++ {
+		idx := (t.lastMinute + i) % int64(activeUsersBuckets)
+		t.buckets[idx] = make(map[int64]struct{})
+	}
+	t.lastMinute = minute
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"container/list"
+	"sync"
+)
+
+// seenChannelCapacity bounds how many distinct (scope, channel) pairs
+// seenChannelTracker remembers. There's no unsubscribe/channel-teardown
+// signal to expire an entry on, so instead of growing forever the tracker
+// evicts the least-recently-seen pair once full, trading a small amount of
+// ChannelsTotal over-counting (an evicted, still-live channel looks "new"
+// again on its next subscribe) for a fixed memory ceiling.
+const seenChannelCapacity = 100_000
+
+// seenChannelTracker records which (scope, channel) pairs have been
+// observed, so ChannelsTotal counts distinct channels rather than subscribe
+// events, within a bounded amount of memory.
+type seenChannelTracker struct {
+	mu       sync.Mutex
+	list     *list.List // of string keys, most-recently-seen at the front
+	elements map[string]*list.Element
+}
+
+func newSeenChannelTracker() *seenChannelTracker {
+	return &seenChannelTracker{
+		list:     list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// markSeen records that channel was subscribed to under scope, returning
+// true if this pair is new (or was evicted since last seen).
+func (t *seenChannelTracker) markSeen(scope, channel string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := scope + "/" + channel
+	if e, ok := t.elements[key]; ok {
+		t.list.MoveToFront(e)
+		return false
+	}
+
+	t.elements[key] = t.list.PushFront(key)
+	if t.list.Len() > seenChannelCapacity {
+		t.evictOldest()
+	}
+	return true
+}
+
+// evictOldest drops the least-recently-seen entry. Must be called with
+// t.mu held.
+func (t *seenChannelTracker) evictOldest() {
+	oldest := t.list.Back()
+	if oldest == nil {
+		return
+	}
+	t.list.Remove(oldest)
+	delete(t.elements, oldest.Value.(string))
+}
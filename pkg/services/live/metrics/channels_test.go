@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeenChannelTracker_MarksNewPairsOnce(t *testing.T) {
+	tr := newSeenChannelTracker()
+
+	assert.True(t, tr.markSeen("grafana", "a"))
+	assert.False(t, tr.markSeen("grafana", "a"))
+	assert.True(t, tr.markSeen("grafana", "b"))
+	assert.True(t, tr.markSeen("plugin", "a"))
+}
+
+func TestSeenChannelTracker_EvictsLeastRecentlySeenWhenFull(t *testing.T) {
+	tr := newSeenChannelTracker()
+
+	assert.True(t, tr.markSeen("grafana", "oldest"))
+	for i := 0; i < seenChannelCapacity-1; i++ {
+		tr.markSeen("grafana", strconv.Itoa(i))
+	}
+	assert.Equal(t, seenChannelCapacity, tr.list.Len())
+
+	// One more distinct pair evicts "oldest", since it's the
+	// least-recently-seen entry.
+	assert.True(t, tr.markSeen("grafana", "newcomer"))
+	assert.Equal(t, seenChannelCapacity, tr.list.Len())
+	assert.True(t, tr.markSeen("grafana", "oldest"))
+}
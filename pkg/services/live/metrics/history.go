@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// LiveHistory holds Prometheus metrics for the Live channel history store
+// (see live/history), covering both persistence and recover-on-reconnect.
+type LiveHistory struct {
+	PersistDuration      prometheus.Histogram
+	RecoverMessagesTotal prometheus.Counter
+	ActiveJanitors       prometheus.Gauge
+}
+
+// NewLiveHistoryMetrics creates and registers LiveHistory metrics.
+func NewLiveHistoryMetrics(r prometheus.Registerer) *LiveHistory {
+	return &LiveHistory{
+		PersistDuration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "history_persist_duration_seconds",
+			Help:      "Histogram of write times to the Live channel history store.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		RecoverMessagesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "state_history_recover_messages_total",
+			Help:      "The total number of history messages replayed to clients recovering a subscription.",
+		}),
+		ActiveJanitors: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "history_active_janitors",
+			Help:      "The number of history janitor sweeps currently in flight.",
+		}),
+	}
+}
@@ -0,0 +1,114 @@
+// Package metrics holds Prometheus instrumentation for Grafana Live itself,
+// as opposed to the channels it forwards (see live/remotewrite for those).
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace = "grafana"
+	subsystem = "live"
+)
+
+// Live holds Prometheus metrics describing Grafana Live subscription and
+// publish activity, independent of any particular channel handler.
+type Live struct {
+	// SubscribersTotal and ChannelsTotal are cumulative, not "active": Live's
+	// ChannelHandler interface has no OnUnsubscribe, so there's no event to
+	// decrement a gauge on. SubscribersTotal counts every successful
+	// subscribe; ChannelsTotal counts the first subscribe seen for each
+	// distinct channel.
+	SubscribersTotal    *prometheus.CounterVec
+	ChannelsTotal       *prometheus.CounterVec
+	SubscribeTotal      *prometheus.CounterVec
+	SubscribeFailed     *prometheus.CounterVec
+	PublishTotal        *prometheus.CounterVec
+	PublishFailed       *prometheus.CounterVec
+	MessagesSentBytes   prometheus.Counter
+	ActiveUsersLastHour prometheus.Gauge
+
+	activeUsers  *activeUserTracker
+	seenChannels *seenChannelTracker
+}
+
+// NewLiveMetrics creates and registers Live metrics.
+func NewLiveMetrics(r prometheus.Registerer) *Live {
+	tracker := newActiveUserTracker()
+
+	m := &Live{
+		SubscribersTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "subscribers_total",
+			Help:      "The total number of successful channel subscribes, labeled by scope and channel namespace.",
+		}, []string{"scope", "namespace"}),
+		ChannelsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "channels_total",
+			Help:      "The total number of distinct channels that have had at least one subscriber, labeled by scope.",
+		}, []string{"scope"}),
+		SubscribeTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "subscribe_total",
+			Help:      "The total number of subscribe attempts, labeled by outcome.",
+		}, []string{"scope", "result"}),
+		SubscribeFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "subscribe_failed_total",
+			Help:      "The total number of subscribe attempts that failed.",
+		}, []string{"scope"}),
+		PublishTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "publish_total",
+			Help:      "The total number of publish attempts, labeled by outcome.",
+		}, []string{"scope", "result"}),
+		PublishFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "publish_failed_total",
+			Help:      "The total number of publish attempts that failed.",
+		}, []string{"scope"}),
+		MessagesSentBytes: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "messages_sent_bytes_total",
+			Help:      "The total size in bytes of messages sent through Live channels.",
+		}),
+		ActiveUsersLastHour: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "active_users_last_hour",
+			Help:      "The number of distinct users that subscribed to or published on a channel in the last hour.",
+		}),
+		activeUsers:  tracker,
+		seenChannels: newSeenChannelTracker(),
+	}
+
+	return m
+}
+
+// TrackUserActivity records that userID interacted with Live (subscribe or
+// publish) at the current time, and refreshes ActiveUsersLastHour.
+func (m *Live) TrackUserActivity(userID int64) {
+	now := time.Now()
+	m.activeUsers.track(userID, now)
+	m.ActiveUsersLastHour.Set(float64(m.activeUsers.count(now)))
+}
+
+// TrackChannelSubscribe records a successful subscribe to channel under
+// scope, incrementing SubscribersTotal and, the first time this channel is
+// seen for scope, ChannelsTotal.
+func (m *Live) TrackChannelSubscribe(scope, namespace, channel string) {
+	m.SubscribersTotal.WithLabelValues(scope, namespace).Inc()
+	if m.seenChannels.markSeen(scope, channel) {
+		m.ChannelsTotal.WithLabelValues(scope).Inc()
+	}
+}
@@ -0,0 +1,77 @@
+package live
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/models"
+	livemetrics "github.com/grafana/grafana/pkg/services/live/metrics"
+)
+
+// metricsMiddleware wraps a models.ChannelHandler so that every subscribe
+// and publish call observed by core channel factories is reflected in the
+// Live metrics.
+type metricsMiddleware struct {
+	next    models.ChannelHandler
+	metrics *livemetrics.Live
+	scope   string
+}
+
+// WithMetrics wraps next so its OnSubscribe/OnPublish calls are counted
+// against scope (e.g. "grafana", "plugin", "stream") in m. Core
+// ChannelHandlerFactory implementations should wrap the handlers they
+// return with this so dashboards get consistent coverage regardless of
+// channel type.
+func WithMetrics(scope string, next models.ChannelHandler, m *livemetrics.Live) models.ChannelHandler {
+	return &metricsMiddleware{next: next, metrics: m, scope: scope}
+}
+
+func (h *metricsMiddleware) OnSubscribe(ctx context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	reply, status, err := h.next.OnSubscribe(ctx, user, e)
+
+	result := "ok"
+	if err != nil || status != backend.SubscribeStreamStatusOK {
+		result = "error"
+		h.metrics.SubscribeFailed.WithLabelValues(h.scope).Inc()
+	} else {
+		h.metrics.TrackChannelSubscribe(h.scope, channelNamespace(e.Channel), e.Channel)
+	}
+	h.metrics.SubscribeTotal.WithLabelValues(h.scope, result).Inc()
+
+	if user != nil {
+		h.metrics.TrackUserActivity(user.UserId)
+	}
+
+	return reply, status, err
+}
+
+func (h *metricsMiddleware) OnPublish(ctx context.Context, user *models.SignedInUser, e models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	reply, status, err := h.next.OnPublish(ctx, user, e)
+
+	result := "ok"
+	if err != nil || status != backend.PublishStreamStatusOK {
+		result = "error"
+		h.metrics.PublishFailed.WithLabelValues(h.scope).Inc()
+	} else {
+		h.metrics.MessagesSentBytes.Add(float64(len(e.Data)))
+	}
+	h.metrics.PublishTotal.WithLabelValues(h.scope, result).Inc()
+
+	if user != nil {
+		h.metrics.TrackUserActivity(user.UserId)
+	}
+
+	return reply, status, err
+}
+
+// channelNamespace returns the first segment of a channel path, e.g.
+// "grafana/dashboard/abc" -> "grafana", used as the namespace label on
+// SubscribersTotal so it stays low-cardinality.
+func channelNamespace(channel string) string {
+	if idx := strings.IndexByte(channel, '/'); idx >= 0 {
+		return channel[:idx]
+	}
+	return channel
+}
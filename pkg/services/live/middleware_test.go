@@ -0,0 +1,82 @@
+package live
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	livemetrics "github.com/grafana/grafana/pkg/services/live/metrics"
+)
+
+type fakeChannelHandler struct {
+	subscribeStatus backend.SubscribeStreamStatus
+	subscribeErr    error
+	publishStatus   backend.PublishStreamStatus
+	publishErr      error
+}
+
+func (h *fakeChannelHandler) OnSubscribe(ctx context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	return models.SubscribeReply{}, h.subscribeStatus, h.subscribeErr
+}
+
+func (h *fakeChannelHandler) OnPublish(ctx context.Context, user *models.SignedInUser, e models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, h.publishStatus, h.publishErr
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, c.Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+func TestWithMetrics_OnSubscribe(t *testing.T) {
+	m := livemetrics.NewLiveMetrics(prometheus.NewRegistry())
+	next := &fakeChannelHandler{subscribeStatus: backend.SubscribeStreamStatusOK}
+	handler := WithMetrics("grafana", next, m)
+
+	_, status, err := handler.OnSubscribe(context.Background(), &models.SignedInUser{UserId: 1}, models.SubscribeEvent{Channel: "grafana/dashboard/abc"})
+	require.NoError(t, err)
+	assert.Equal(t, backend.SubscribeStreamStatusOK, status)
+
+	assert.Equal(t, float64(1), counterValue(t, m.SubscribersTotal.WithLabelValues("grafana", "grafana")))
+	assert.Equal(t, float64(1), counterValue(t, m.ChannelsTotal.WithLabelValues("grafana")))
+	assert.Equal(t, float64(1), counterValue(t, m.SubscribeTotal.WithLabelValues("grafana", "ok")))
+	assert.Equal(t, float64(0), counterValue(t, m.SubscribeFailed.WithLabelValues("grafana")))
+
+	// A second subscribe to the same channel must not double-count
+	// ChannelsTotal, only SubscribersTotal.
+	_, _, err = handler.OnSubscribe(context.Background(), &models.SignedInUser{UserId: 2}, models.SubscribeEvent{Channel: "grafana/dashboard/abc"})
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), counterValue(t, m.SubscribersTotal.WithLabelValues("grafana", "grafana")))
+	assert.Equal(t, float64(1), counterValue(t, m.ChannelsTotal.WithLabelValues("grafana")))
+}
+
+func TestWithMetrics_OnSubscribeFailed(t *testing.T) {
+	m := livemetrics.NewLiveMetrics(prometheus.NewRegistry())
+	next := &fakeChannelHandler{subscribeStatus: backend.SubscribeStreamStatusError}
+	handler := WithMetrics("grafana", next, m)
+
+	_, _, err := handler.OnSubscribe(context.Background(), nil, models.SubscribeEvent{Channel: "grafana/dashboard/abc"})
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(1), counterValue(t, m.SubscribeFailed.WithLabelValues("grafana")))
+	assert.Equal(t, float64(0), counterValue(t, m.ChannelsTotal.WithLabelValues("grafana")))
+}
+
+func TestWithMetrics_OnPublish(t *testing.T) {
+	m := livemetrics.NewLiveMetrics(prometheus.NewRegistry())
+	next := &fakeChannelHandler{publishStatus: backend.PublishStreamStatusOK}
+	handler := WithMetrics("grafana", next, m)
+
+	_, status, err := handler.OnPublish(context.Background(), &models.SignedInUser{UserId: 1}, models.PublishEvent{Channel: "grafana/dashboard/abc", Data: []byte("hello")})
+	require.NoError(t, err)
+	assert.Equal(t, backend.PublishStreamStatusOK, status)
+	assert.Equal(t, float64(1), counterValue(t, m.PublishTotal.WithLabelValues("grafana", "ok")))
+}
@@ -0,0 +1,58 @@
+package remotewrite
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace = "grafana"
+	subsystem = "live_remote_write"
+)
+
+// Metrics holds Prometheus metrics for the Live remote write pipeline.
+type Metrics struct {
+	SamplesTotal      *prometheus.CounterVec
+	HistogramsTotal   *prometheus.CounterVec
+	ExemplarsTotal    *prometheus.CounterVec
+	RetriesTotal      *prometheus.CounterVec
+	ConfigErrorsTotal *prometheus.CounterVec
+}
+
+// NewMetrics creates and registers remote write metrics. The write and retry
+// counters are labeled by the protocol version ("1.0" or "2.0") used for the
+// request; ConfigErrorsTotal is labeled by reason.
+func NewMetrics(r prometheus.Registerer) *Metrics {
+	return &Metrics{
+		SamplesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "samples_total",
+			Help:      "The total number of samples successfully written to the remote write endpoint.",
+		}, []string{"protocol_version"}),
+		HistogramsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "histograms_total",
+			Help:      "The total number of native histograms successfully written to the remote write endpoint.",
+		}, []string{"protocol_version"}),
+		ExemplarsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "exemplars_total",
+			Help:      "The total number of exemplars successfully written to the remote write endpoint.",
+		}, []string{"protocol_version"}),
+		RetriesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retries_total",
+			Help:      "The total number of remote write requests retried, including protocol version fallback.",
+		}, []string{"protocol_version"}),
+		ConfigErrorsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "config_errors_total",
+			Help:      "The total number of remote write configuration errors, e.g. unparsable TLS material.",
+		}, []string{"reason"}),
+	}
+}
@@ -0,0 +1,234 @@
+// Package remotewrite sends Grafana Live channel frames to a Prometheus
+// remote write endpoint on behalf of a LiveChannelConfig.
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	headerContentEncoding   = "Content-Encoding"
+	headerContentType       = "Content-Type"
+	headerRemoteWriteVerV1  = "0.1.0"
+	headerRemoteWriteVerV2  = "2.0.0"
+	headerRemoteWriteVerKey = "X-Prometheus-Remote-Write-Version"
+
+	contentTypeV1 = "application/x-protobuf"
+	contentTypeV2 = "application/x-protobuf;proto=io.prometheus.write.v2.Request"
+
+	headerWrittenSamples   = "X-Prometheus-Remote-Write-Written-Samples"
+	headerWrittenHistograms = "X-Prometheus-Remote-Write-Written-Histograms"
+	headerWrittenExemplars  = "X-Prometheus-Remote-Write-Written-Exemplars"
+)
+
+// WriteStats reports the partial-write accounting a remote write endpoint
+// returns on a successful (2xx) response. A count the response headers do
+// not report is taken to be the number sent.
+type WriteStats struct {
+	Samples    int64
+	Histograms int64
+	Exemplars  int64
+}
+
+// Sender posts Live channel samples to a single channel's configured remote
+// write endpoint, negotiating the protocol version declared on the channel
+// config and falling back to 1.0 when the endpoint rejects 2.0.
+type Sender struct {
+	client   *http.Client
+	endpoint string
+	metrics  *Metrics
+}
+
+// NewSender builds a Sender for a channel's remote write endpoint. client is
+// expected to already carry any auth/TLS configuration for the channel.
+func NewSender(client *http.Client, endpoint string, metrics *Metrics) *Sender {
+	return &Sender{client: client, endpoint: endpoint, metrics: metrics}
+}
+
+// Send writes series to the remote write endpoint using protocolVersion,
+// one of models.RemoteWriteProtocolVersion10 or RemoteWriteProtocolVersion20.
+// metadata carries the type/help/unit for the metric families in series,
+// matched to a series by its "__name__" label; it's only used when writing
+// 2.0, which inlines metadata into the series message instead of a separate
+// metadata stream. On a 400/415 response to a 2.0 request it retries once as
+// 1.0.
+func (s *Sender) Send(ctx context.Context, protocolVersion string, series []prompb.TimeSeries, metadata []prompb.MetricMetadata) (WriteStats, error) {
+	if protocolVersion == "" {
+		protocolVersion = models.RemoteWriteProtocolVersion10
+	}
+
+	stats, resp, err := s.send(ctx, protocolVersion, series, metadata)
+	if err != nil {
+		return WriteStats{}, err
+	}
+	defer resp.Body.Close()
+
+	if protocolVersion == models.RemoteWriteProtocolVersion20 && (resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnsupportedMediaType) {
+		s.metrics.RetriesTotal.WithLabelValues(protocolVersion).Inc()
+		stats, resp, err = s.send(ctx, models.RemoteWriteProtocolVersion10, series, metadata)
+		if err != nil {
+			return WriteStats{}, err
+		}
+		defer resp.Body.Close()
+		protocolVersion = models.RemoteWriteProtocolVersion10
+	}
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return WriteStats{}, fmt.Errorf("remote write endpoint returned %s: %s", resp.Status, body)
+	}
+
+	stats = statsFromHeaders(resp.Header, stats)
+	s.metrics.SamplesTotal.WithLabelValues(protocolVersion).Add(float64(stats.Samples))
+	s.metrics.HistogramsTotal.WithLabelValues(protocolVersion).Add(float64(stats.Histograms))
+	s.metrics.ExemplarsTotal.WithLabelValues(protocolVersion).Add(float64(stats.Exemplars))
+
+	return stats, nil
+}
+
+func (s *Sender) send(ctx context.Context, protocolVersion string, series []prompb.TimeSeries, metadata []prompb.MetricMetadata) (WriteStats, *http.Response, error) {
+	var (
+		payload     []byte
+		err         error
+		contentType string
+		writeVer    string
+	)
+
+	switch protocolVersion {
+	case models.RemoteWriteProtocolVersion20:
+		payload, err = encodeV2(series, metadata)
+		contentType = contentTypeV2
+		writeVer = headerRemoteWriteVerV2
+	default:
+		payload, err = encodeV1(series)
+		contentType = contentTypeV1
+		writeVer = headerRemoteWriteVerV1
+	}
+	if err != nil {
+		return WriteStats{}, nil, fmt.Errorf("encode remote write payload: %w", err)
+	}
+
+	compressed := snappy.Encode(nil, payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(compressed))
+	if err != nil {
+		return WriteStats{}, nil, err
+	}
+	req.Header.Set(headerContentEncoding, "snappy")
+	req.Header.Set(headerContentType, contentType)
+	req.Header.Set(headerRemoteWriteVerKey, writeVer)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return WriteStats{}, nil, err
+	}
+
+	var sent WriteStats
+	for _, ts := range series {
+		sent.Samples += int64(len(ts.Samples))
+		sent.Histograms += int64(len(ts.Histograms))
+		sent.Exemplars += int64(len(ts.Exemplars))
+	}
+	return sent, resp, nil
+}
+
+// encodeV1 marshals series as a prometheus.WriteRequest (PRW 1.0).
+func encodeV1(series []prompb.TimeSeries) ([]byte, error) {
+	return (&prompb.WriteRequest{Timeseries: series}).Marshal()
+}
+
+// encodeV2 marshals series as an io.prometheus.write.v2.Request (PRW 2.0),
+// interning all label names/values and metadata strings into a single
+// symbols table referenced by index from each TimeSeries. Each series'
+// metadata is looked up in metadata by its "__name__" label, matching how
+// the metric family is identified in the 1.0 metadata stream, and travels
+// inline on the series message instead of a separate stream.
+func encodeV2(series []prompb.TimeSeries, metadata []prompb.MetricMetadata) ([]byte, error) {
+	symbols := newSymbolTable()
+	out := make([]writev2.TimeSeries, 0, len(series))
+
+	for _, ts := range series {
+		v2ts := writev2.TimeSeries{
+			Samples:    convertSamples(ts.Samples),
+			Exemplars:  convertExemplars(ts.Exemplars, symbols),
+			Histograms: convertHistograms(ts.Histograms),
+		}
+		v2ts.LabelsRefs = make([]uint32, 0, len(ts.Labels)*2)
+		var metricName string
+		for _, l := range ts.Labels {
+			v2ts.LabelsRefs = append(v2ts.LabelsRefs, symbols.ref(l.Name), symbols.ref(l.Value))
+			if l.Name == "__name__" {
+				metricName = l.Value
+			}
+		}
+		if md, ok := metadataFor(metricName, metadata); ok {
+			v2ts.Metadata = writev2.Metadata{
+				Type:    convertMetricType(md.Type),
+				HelpRef: symbols.ref(md.Help),
+				UnitRef: symbols.ref(md.Unit),
+			}
+		}
+		out = append(out, v2ts)
+	}
+
+	return (&writev2.Request{
+		Symbols:    symbols.strings,
+		Timeseries: out,
+	}).Marshal()
+}
+
+// metadataFor returns the entry in metadata describing the metric family
+// named name, as identified by MetricFamilyName in the 1.0 metadata stream.
+func metadataFor(name string, metadata []prompb.MetricMetadata) (prompb.MetricMetadata, bool) {
+	if name == "" {
+		return prompb.MetricMetadata{}, false
+	}
+	for _, md := range metadata {
+		if md.MetricFamilyName == name {
+			return md, true
+		}
+	}
+	return prompb.MetricMetadata{}, false
+}
+
+// convertMetricType maps the 1.0 MetricMetadata type enum to its 2.0
+// equivalent; the two enums share the same value ordering.
+func convertMetricType(t prompb.MetricMetadata_MetricType) writev2.Metadata_MetricType {
+	return writev2.Metadata_MetricType(t)
+}
+
+func statsFromHeaders(h http.Header, stats WriteStats) WriteStats {
+	if v, ok := parseIntHeader(h, headerWrittenSamples); ok {
+		stats.Samples = v
+	}
+	if v, ok := parseIntHeader(h, headerWrittenHistograms); ok {
+		stats.Histograms = v
+	}
+	if v, ok := parseIntHeader(h, headerWrittenExemplars); ok {
+		stats.Exemplars = v
+	}
+	return stats
+}
+
+func parseIntHeader(h http.Header, key string) (int64, bool) {
+	raw := h.Get(key)
+	if raw == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
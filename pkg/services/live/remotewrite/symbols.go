@@ -0,0 +1,123 @@
+package remotewrite
+
+import (
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+)
+
+// symbolTable interns strings for a single PRW 2.0 request, assigning each
+// distinct string a stable index into the request's symbols table. Index 0
+// is reserved for the empty string, per the PRW 2.0 wire format.
+type symbolTable struct {
+	strings []string
+	index   map[string]uint32
+}
+
+func newSymbolTable() *symbolTable {
+	return &symbolTable{
+		strings: []string{""},
+		index:   map[string]uint32{"": 0},
+	}
+}
+
+func (t *symbolTable) ref(s string) uint32 {
+	if ref, ok := t.index[s]; ok {
+		return ref
+	}
+	ref := uint32(len(t.strings))
+	t.strings = append(t.strings, s)
+	t.index[s] = ref
+	return ref
+}
+
+func convertSamples(in []prompb.Sample) []writev2.Sample {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]writev2.Sample, len(in))
+	for i, s := range in {
+		out[i] = writev2.Sample{Value: s.Value, Timestamp: s.Timestamp}
+	}
+	return out
+}
+
+func convertExemplars(in []prompb.Exemplar, symbols *symbolTable) []writev2.Exemplar {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]writev2.Exemplar, len(in))
+	for i, e := range in {
+		labelsRefs := make([]uint32, 0, len(e.Labels)*2)
+		for _, l := range e.Labels {
+			labelsRefs = append(labelsRefs, symbols.ref(l.Name), symbols.ref(l.Value))
+		}
+		out[i] = writev2.Exemplar{
+			LabelsRefs: labelsRefs,
+			Value:      e.Value,
+			Timestamp:  e.Timestamp,
+		}
+	}
+	return out
+}
+
+func convertHistograms(in []prompb.Histogram) []writev2.Histogram {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]writev2.Histogram, len(in))
+	for i, h := range in {
+		out[i] = convertHistogram(h)
+	}
+	return out
+}
+
+// convertHistogram copies a single prompb.Histogram to its PRW 2.0
+// equivalent. Count and ZeroCount are oneofs: a histogram carries either the
+// int or float variant, never both, so which one is set must be checked
+// rather than always taking the int getter (which silently returns 0 for a
+// float-count histogram). PositiveCounts/NegativeCounts/ResetHint/
+// CustomValues cover native histograms with explicit bucket counts or
+// custom bucket boundaries (NHCB), which span/delta conversion alone loses.
+func convertHistogram(h prompb.Histogram) writev2.Histogram {
+	out := writev2.Histogram{
+		Sum:            h.Sum,
+		Schema:         h.Schema,
+		ZeroThreshold:  h.ZeroThreshold,
+		NegativeSpans:  convertSpans(h.NegativeSpans),
+		NegativeDeltas: h.NegativeDeltas,
+		NegativeCounts: h.NegativeCounts,
+		PositiveSpans:  convertSpans(h.PositiveSpans),
+		PositiveDeltas: h.PositiveDeltas,
+		PositiveCounts: h.PositiveCounts,
+		CustomValues:   h.CustomValues,
+		ResetHint:      writev2.Histogram_ResetHint(h.ResetHint),
+		Timestamp:      h.Timestamp,
+	}
+
+	switch h.Count.(type) {
+	case *prompb.Histogram_CountFloat:
+		out.Count = writev2.Histogram_CountFloat{CountFloat: h.GetCountFloat()}
+	default:
+		out.Count = writev2.Histogram_CountInt{CountInt: h.GetCountInt()}
+	}
+
+	switch h.ZeroCount.(type) {
+	case *prompb.Histogram_ZeroCountFloat:
+		out.ZeroCount = writev2.Histogram_ZeroCountFloat{ZeroCountFloat: h.GetZeroCountFloat()}
+	default:
+		out.ZeroCount = writev2.Histogram_ZeroCountInt{ZeroCountInt: h.GetZeroCountInt()}
+	}
+
+	return out
+}
+
+func convertSpans(in []prompb.BucketSpan) []writev2.BucketSpan {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]writev2.BucketSpan, len(in))
+	for i, s := range in {
+		out[i] = writev2.BucketSpan{Offset: s.Offset, Length: s.Length}
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+package remotewrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolTable_EmptyStringIsIndexZero(t *testing.T) {
+	symbols := newSymbolTable()
+	assert.Equal(t, uint32(0), symbols.ref(""))
+	assert.Equal(t, []string{""}, symbols.strings)
+}
+
+func TestSymbolTable_InternsRepeatedStrings(t *testing.T) {
+	symbols := newSymbolTable()
+
+	ref1 := symbols.ref("job")
+	ref2 := symbols.ref("instance")
+	ref3 := symbols.ref("job")
+
+	assert.Equal(t, ref1, ref3)
+	assert.NotEqual(t, ref1, ref2)
+	assert.Equal(t, []string{"", "job", "instance"}, symbols.strings)
+}
+
+func TestEncodeV2_InlinesMetadataOnTheMatchingSeries(t *testing.T) {
+	series := []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "up"},
+				{Name: "job", Value: "grafana"},
+			},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+	}
+	metadata := []prompb.MetricMetadata{
+		{MetricFamilyName: "up", Type: prompb.MetricMetadata_GAUGE, Help: "Whether the target is up.", Unit: "bool"},
+	}
+
+	payload, err := encodeV2(series, metadata)
+	require.NoError(t, err)
+
+	var req writev2.Request
+	require.NoError(t, req.Unmarshal(payload))
+	require.Len(t, req.Timeseries, 1)
+
+	got := req.Timeseries[0]
+	assert.Equal(t, writev2.Metadata_METRIC_TYPE_GAUGE, got.Metadata.Type)
+	require.Less(t, int(got.Metadata.HelpRef), len(req.Symbols))
+	require.Less(t, int(got.Metadata.UnitRef), len(req.Symbols))
+	assert.Equal(t, "Whether the target is up.", req.Symbols[got.Metadata.HelpRef])
+	assert.Equal(t, "bool", req.Symbols[got.Metadata.UnitRef])
+}
+
+func TestEncodeV2_NoMetadataMatchLeavesMetadataZeroValue(t *testing.T) {
+	series := []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+	}
+
+	payload, err := encodeV2(series, nil)
+	require.NoError(t, err)
+
+	var req writev2.Request
+	require.NoError(t, req.Unmarshal(payload))
+	require.Len(t, req.Timeseries, 1)
+	assert.Equal(t, writev2.Metadata{}, req.Timeseries[0].Metadata)
+}
+
+func TestConvertHistogram_FloatCount(t *testing.T) {
+	in := prompb.Histogram{
+		Count:         &prompb.Histogram_CountFloat{CountFloat: 12.5},
+		Sum:           99.9,
+		Schema:        3,
+		ZeroThreshold: 0.001,
+		ZeroCount:     &prompb.Histogram_ZeroCountFloat{ZeroCountFloat: 1.5},
+		Timestamp:     1000,
+	}
+
+	out := convertHistogram(in)
+
+	require.IsType(t, writev2.Histogram_CountFloat{}, out.Count)
+	assert.Equal(t, 12.5, out.Count.(writev2.Histogram_CountFloat).CountFloat)
+	require.IsType(t, writev2.Histogram_ZeroCountFloat{}, out.ZeroCount)
+	assert.Equal(t, 1.5, out.ZeroCount.(writev2.Histogram_ZeroCountFloat).ZeroCountFloat)
+	assert.Equal(t, 99.9, out.Sum)
+}
+
+func TestConvertHistogram_CustomBucketsAndResetHint(t *testing.T) {
+	in := prompb.Histogram{
+		Count:          &prompb.Histogram_CountInt{CountInt: 42},
+		ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 2},
+		PositiveCounts: []float64{1, 2, 3},
+		NegativeCounts: []float64{4, 5},
+		CustomValues:   []float64{0.1, 0.5, 1},
+		ResetHint:      prompb.Histogram_GAUGE,
+		Timestamp:      1000,
+	}
+
+	out := convertHistogram(in)
+
+	require.IsType(t, writev2.Histogram_CountInt{}, out.Count)
+	assert.Equal(t, uint64(42), out.Count.(writev2.Histogram_CountInt).CountInt)
+	assert.Equal(t, []float64{1, 2, 3}, out.PositiveCounts)
+	assert.Equal(t, []float64{4, 5}, out.NegativeCounts)
+	assert.Equal(t, []float64{0.1, 0.5, 1}, out.CustomValues)
+	assert.Equal(t, writev2.Histogram_GAUGE, out.ResetHint)
+}
+
+func TestEncodeV2_RoundTripsHistogram(t *testing.T) {
+	series := []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{{Name: "__name__", Value: "latency"}},
+			Histograms: []prompb.Histogram{
+				{
+					Count:        &prompb.Histogram_CountFloat{CountFloat: 7},
+					CustomValues: []float64{0.1, 1, 10},
+					Timestamp:    1000,
+				},
+			},
+		},
+	}
+
+	payload, err := encodeV2(series, nil)
+	require.NoError(t, err)
+
+	var req writev2.Request
+	require.NoError(t, req.Unmarshal(payload))
+	require.Len(t, req.Timeseries, 1)
+	require.Len(t, req.Timeseries[0].Histograms, 1)
+
+	got := req.Timeseries[0].Histograms[0]
+	require.IsType(t, writev2.Histogram_CountFloat{}, got.Count)
+	assert.Equal(t, float64(7), got.Count.(writev2.Histogram_CountFloat).CountFloat)
+	assert.Equal(t, []float64{0.1, 1, 10}, got.CustomValues)
+}
@@ -0,0 +1,183 @@
+package remotewrite
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// TransportCache builds and reuses *http.Transport instances across channel
+// config reloads, keyed by a fingerprint of the TLS material in play, so
+// re-saving a channel's remote write config doesn't leak a fresh transport
+// (and its idle connections) on every reload.
+type TransportCache struct {
+	metrics *Metrics
+
+	mu    sync.Mutex
+	cache map[string]*http.Transport
+}
+
+// NewTransportCache creates a TransportCache. metrics.ConfigErrorsTotal is
+// incremented with reason="tls_config" whenever cert/key material fails to
+// parse.
+func NewTransportCache(metrics *Metrics) *TransportCache {
+	return &TransportCache{metrics: metrics, cache: make(map[string]*http.Transport)}
+}
+
+// Client builds an *http.Client for channel's remote write config, applying
+// whichever single auth method plain/secure select (see
+// LiveChannelSecureConfig.AuthMethod) and reusing a cached transport when
+// the TLS material is unchanged.
+func (c *TransportCache) Client(plain models.LiveChannelPlainConfig, secure models.LiveChannelSecureConfig) (*http.Client, error) {
+	method, err := secure.AuthMethod()
+	if err != nil {
+		c.metrics.ConfigErrorsTotal.WithLabelValues("tls_config").Inc()
+		return nil, err
+	}
+
+	transport, err := c.transportFor(plain, secure)
+	if err != nil {
+		c.metrics.ConfigErrorsTotal.WithLabelValues("tls_config").Inc()
+		return nil, err
+	}
+
+	client := &http.Client{Transport: transport}
+
+	switch method {
+	case "basic":
+		client.Transport = &basicAuthTransport{
+			next:     transport,
+			user:     secure.RemoteWriteUser,
+			password: secure.RemoteWritePassword,
+		}
+	case "bearer":
+		token, err := bearerToken(secure)
+		if err != nil {
+			c.metrics.ConfigErrorsTotal.WithLabelValues("tls_config").Inc()
+			return nil, err
+		}
+		client.Transport = &bearerAuthTransport{next: transport, token: token}
+	}
+
+	return client, nil
+}
+
+func (c *TransportCache) transportFor(plain models.LiveChannelPlainConfig, secure models.LiveChannelSecureConfig) (*http.Transport, error) {
+	fingerprint, err := fingerprintOf(plain, secure)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.cache[fingerprint]; ok {
+		return t, nil
+	}
+
+	tlsConfig, err := buildTLSConfig(plain, secure)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	if plain.ProxyURL != "" {
+		proxyURL, err := url.Parse(plain.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxyUrl: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	c.cache[fingerprint] = transport
+	return transport, nil
+}
+
+func buildTLSConfig(plain models.LiveChannelPlainConfig, secure models.LiveChannelSecureConfig) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         plain.TLSServerName,
+		InsecureSkipVerify: plain.TLSInsecureSkipVerify,
+	}
+
+	if secure.TLSCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(secure.TLSCACert)) {
+			return nil, fmt.Errorf("parse tlsCACert: no certificates found")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if secure.TLSClientCert != "" || secure.TLSClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(secure.TLSClientCert), []byte(secure.TLSClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("parse mTLS client cert/key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func bearerToken(secure models.LiveChannelSecureConfig) (string, error) {
+	if secure.BearerToken != "" {
+		return secure.BearerToken, nil
+	}
+	b, err := os.ReadFile(secure.BearerTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("read bearerTokenFile: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// fingerprintOf hashes the TLS/proxy material that determines a transport's
+// identity, so identical configs across a reload map to the same cached
+// transport instead of spawning a new one (and its own idle connections).
+func fingerprintOf(plain models.LiveChannelPlainConfig, secure models.LiveChannelSecureConfig) (string, error) {
+	h := sha256.New()
+	for _, s := range []string{
+		plain.TLSServerName,
+		plain.ProxyURL,
+		secure.TLSCACert,
+		secure.TLSClientCert,
+		secure.TLSClientKey,
+	} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	if plain.TLSInsecureSkipVerify {
+		_, _ = h.Write([]byte{1})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type basicAuthTransport struct {
+	next     http.RoundTripper
+	user     string
+	password string
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.user, t.password)
+	return t.next.RoundTrip(req)
+}
+
+type bearerAuthTransport struct {
+	next  http.RoundTripper
+	token string
+}
+
+func (t *bearerAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.next.RoundTrip(req)
+}
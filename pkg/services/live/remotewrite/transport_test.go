@@ -0,0 +1,139 @@
+package remotewrite
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// testCert generates a throwaway self-signed cert/key PEM pair.
+func testCert(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	return certPEM, keyPEM
+}
+
+func TestBuildTLSConfig_InvalidCACert(t *testing.T) {
+	_, err := buildTLSConfig(models.LiveChannelPlainConfig{}, models.LiveChannelSecureConfig{TLSCACert: "not a pem"})
+	require.Error(t, err)
+}
+
+func TestBuildTLSConfig_InvalidClientKeyPair(t *testing.T) {
+	certPEM, _ := testCert(t)
+	_, err := buildTLSConfig(models.LiveChannelPlainConfig{}, models.LiveChannelSecureConfig{TLSClientCert: certPEM, TLSClientKey: "not a key"})
+	require.Error(t, err)
+}
+
+func TestBuildTLSConfig_ValidMaterial(t *testing.T) {
+	certPEM, keyPEM := testCert(t)
+	cfg, err := buildTLSConfig(models.LiveChannelPlainConfig{TLSServerName: "example.com"}, models.LiveChannelSecureConfig{
+		TLSCACert:     certPEM,
+		TLSClientCert: certPEM,
+		TLSClientKey:  keyPEM,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", cfg.ServerName)
+	assert.NotNil(t, cfg.RootCAs)
+	require.Len(t, cfg.Certificates, 1)
+}
+
+func TestTransportCache_Client_RejectsMultipleAuthMethods(t *testing.T) {
+	c := NewTransportCache(NewMetrics(prometheus.NewRegistry()))
+	_, err := c.Client(models.LiveChannelPlainConfig{}, models.LiveChannelSecureConfig{
+		RemoteWriteUser: "user",
+		BearerToken:     "token",
+	})
+	require.Error(t, err)
+}
+
+func TestTransportCache_Client_BasicAuth(t *testing.T) {
+	c := NewTransportCache(NewMetrics(prometheus.NewRegistry()))
+	client, err := c.Client(models.LiveChannelPlainConfig{}, models.LiveChannelSecureConfig{
+		RemoteWriteUser:     "user",
+		RemoteWritePassword: "pass",
+	})
+	require.NoError(t, err)
+	_, ok := client.Transport.(*basicAuthTransport)
+	assert.True(t, ok)
+}
+
+func TestFingerprintOf_SameMaterialSameFingerprint(t *testing.T) {
+	plain := models.LiveChannelPlainConfig{TLSServerName: "example.com"}
+	secure := models.LiveChannelSecureConfig{TLSCACert: "ca"}
+
+	fp1, err := fingerprintOf(plain, secure)
+	require.NoError(t, err)
+	fp2, err := fingerprintOf(plain, secure)
+	require.NoError(t, err)
+	assert.Equal(t, fp1, fp2)
+}
+
+func TestFingerprintOf_ChangedCertDiffersFingerprint(t *testing.T) {
+	plain := models.LiveChannelPlainConfig{TLSServerName: "example.com"}
+
+	fp1, err := fingerprintOf(plain, models.LiveChannelSecureConfig{TLSCACert: "ca-one"})
+	require.NoError(t, err)
+	fp2, err := fingerprintOf(plain, models.LiveChannelSecureConfig{TLSCACert: "ca-two"})
+	require.NoError(t, err)
+	assert.NotEqual(t, fp1, fp2)
+}
+
+func TestTransportCache_TransportForReusesCachedTransport(t *testing.T) {
+	c := NewTransportCache(NewMetrics(prometheus.NewRegistry()))
+	plain := models.LiveChannelPlainConfig{TLSServerName: "example.com"}
+	secure := models.LiveChannelSecureConfig{}
+
+	t1, err := c.transportFor(plain, secure)
+	require.NoError(t, err)
+	t2, err := c.transportFor(plain, secure)
+	require.NoError(t, err)
+	assert.Same(t, t1, t2)
+}
+
+func TestBearerToken_PrefersInlineToken(t *testing.T) {
+	token, err := bearerToken(models.LiveChannelSecureConfig{BearerToken: "inline-token"})
+	require.NoError(t, err)
+	assert.Equal(t, "inline-token", token)
+}
+
+func TestBearerToken_ReadsAndTrimsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(path, []byte("file-token\n"), 0o600))
+
+	token, err := bearerToken(models.LiveChannelSecureConfig{BearerTokenFile: path})
+	require.NoError(t, err)
+	assert.Equal(t, "file-token", token)
+}
+
+func TestBearerToken_MissingFile(t *testing.T) {
+	_, err := bearerToken(models.LiveChannelSecureConfig{BearerTokenFile: "/nonexistent/token"})
+	require.Error(t, err)
+}
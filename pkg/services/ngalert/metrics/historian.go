@@ -2,50 +2,137 @@ package metrics
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// MetricsOptions configures NewHistorianMetrics.
+type MetricsOptions struct {
+	// ConstLabels are attached to every Historian metric, e.g. to scope a
+	// per-tenant registry. An "org" entry is left off the *ByOrg metrics,
+	// which carry org as a variable label instead.
+	ConstLabels prometheus.Labels
+	// AllowReregister makes NewHistorianMetrics idempotent: a second call
+	// against the same registry returns the already-registered collectors
+	// instead of panicking. Useful in tests and when constructing
+	// per-tenant Historians against a shared registry.
+	AllowReregister bool
+}
+
 type Historian struct {
-	TransitionsTotal      *prometheus.CounterVec
-	TransitionsFailed     *prometheus.CounterVec
+	TransitionsTotal      prometheus.Counter
+	TransitionsFailed     prometheus.Counter
 	WritesTotal           prometheus.Counter
 	WritesFailed          prometheus.Counter
 	ActiveWriteGoroutines prometheus.Gauge
 	PersistDuration       prometheus.Histogram
+
+	// WritesTotalByOrg, WritesFailedByOrg, and PersistDurationByOrg are the
+	// org-labeled counterparts of WritesTotal/WritesFailed/PersistDuration
+	// above, for callers that want a per-org breakdown. The scalar fields
+	// keep aggregating across all orgs so existing callers keep compiling
+	// unchanged.
+	WritesTotalByOrg     *prometheus.CounterVec
+	WritesFailedByOrg    *prometheus.CounterVec
+	PersistDurationByOrg *prometheus.HistogramVec
 }
 
-func NewHistorianMetrics(r prometheus.Registerer) *Historian {
+// NewHistorianMetrics creates Historian metrics on r. With no opts, this
+// panics on duplicate registration exactly as before; pass
+// MetricsOptions{AllowReregister: true} to make it idempotent instead, and
+// MetricsOptions{ConstLabels: ...} to scope the metrics to a tenant.
+func NewHistorianMetrics(r prometheus.Registerer, opts ...MetricsOptions) *Historian {
+	var opt MetricsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	byOrgR := r
+	if len(opt.ConstLabels) > 0 {
+		r = prometheus.WrapRegistererWith(opt.ConstLabels, r)
+
+		byOrgLabels := prometheus.Labels{}
+		for name, value := range opt.ConstLabels {
+			if name != "org" {
+				byOrgLabels[name] = value
+			}
+		}
+		byOrgR = prometheus.WrapRegistererWith(byOrgLabels, byOrgR)
+	}
+
 	return &Historian{
-		TransitionsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+		TransitionsTotal: register(r, opt.AllowReregister, prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: Namespace,
 			Subsystem: Subsystem,
 			Name:      "state_history_transitions_total",
 			Help:      "The total number of state transitions processed by the state historian.",
-		}, []string{"org"}),
-		TransitionsFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+		})),
+		TransitionsFailed: register(r, opt.AllowReregister, prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: Namespace,
 			Subsystem: Subsystem,
 			Name:      "state_history_transitions_failed_total",
 			Help:      "The total number of state transitions that failed to be written.",
-		}, []string{"org"}),
-		WritesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+		})),
+		WritesTotal: register(r, opt.AllowReregister, prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: Namespace,
 			Subsystem: Subsystem,
 			Name:      "state_history_batch_writes_total",
 			Help:      "The total number of state history batches that were attempted to be written.",
-		}),
-		WritesFailed: promauto.With(r).NewCounter(prometheus.CounterOpts{
+		})),
+		WritesFailed: register(r, opt.AllowReregister, prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: Namespace,
 			Subsystem: Subsystem,
 			Name:      "state_history_batch_writes_failed_total",
 			Help:      "The total number of failed writes of state history batches.",
-		}),
-		PersistDuration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+		})),
+		ActiveWriteGoroutines: register(r, opt.AllowReregister, prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "state_history_active_write_goroutines",
+			Help:      "The number of goroutines currently writing state history batches.",
+		})),
+		PersistDuration: register(r, opt.AllowReregister, prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: Namespace,
 			Subsystem: Subsystem,
 			Name:      "state_history_persist_duration_seconds",
 			Help:      "Histogram of write times to the state history store.",
 			Buckets:   prometheus.DefBuckets,
-		}),
+		})),
+		WritesTotalByOrg: register(byOrgR, opt.AllowReregister, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "state_history_batch_writes_total_by_org",
+			Help:      "The total number of state history batches that were attempted to be written, by org.",
+		}, []string{"org"})),
+		WritesFailedByOrg: register(byOrgR, opt.AllowReregister, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "state_history_batch_writes_failed_total_by_org",
+			Help:      "The total number of failed writes of state history batches, by org.",
+		}, []string{"org"})),
+		PersistDurationByOrg: register(byOrgR, opt.AllowReregister, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "state_history_persist_duration_seconds_by_org",
+			Help:      "Histogram of write times to the state history store, by org.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"org"})),
+	}
+}
+
+// register registers c on r. When allowReregister is false it panics on
+// failure, matching promauto's historical behavior. When true, it treats
+// AlreadyRegisteredError as success and returns the already-registered
+// collector instead, making construction idempotent.
+func register[T prometheus.Collector](r prometheus.Registerer, allowReregister bool, c T) T {
+	err := r.Register(c)
+	if err == nil {
+		return c
+	}
+	if allowReregister {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
 	}
+	panic(err)
 }
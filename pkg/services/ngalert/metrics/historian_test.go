@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistorianMetrics_PanicsOnDuplicateRegistrationByDefault(t *testing.T) {
+	r := prometheus.NewRegistry()
+	NewHistorianMetrics(r)
+
+	assert.Panics(t, func() {
+		NewHistorianMetrics(r)
+	})
+}
+
+func TestNewHistorianMetrics_AllowReregisterIsIdempotent(t *testing.T) {
+	r := prometheus.NewRegistry()
+	first := NewHistorianMetrics(r)
+
+	var second *Historian
+	assert.NotPanics(t, func() {
+		second = NewHistorianMetrics(r, MetricsOptions{AllowReregister: true})
+	})
+
+	// The second call must hand back the same already-registered
+	// collectors, not silently-unregistered duplicates.
+	assert.Same(t, first.WritesTotal, second.WritesTotal)
+	assert.Same(t, first.WritesTotalByOrg, second.WritesTotalByOrg)
+	assert.Same(t, first.PersistDuration, second.PersistDuration)
+}
+
+func TestNewHistorianMetrics_ConstLabels(t *testing.T) {
+	r := prometheus.NewRegistry()
+	m := NewHistorianMetrics(r, MetricsOptions{ConstLabels: prometheus.Labels{"org": "1"}})
+
+	m.WritesTotal.Inc()
+
+	var found dto.Metric
+	mfs, err := r.Gather()
+	require.NoError(t, err)
+
+	var got bool
+	for _, mf := range mfs {
+		if !strings.HasSuffix(mf.GetName(), "state_history_batch_writes_total") {
+			continue
+		}
+		require.Len(t, mf.GetMetric(), 1)
+		found = *mf.GetMetric()[0]
+		got = true
+	}
+	require.True(t, got, "expected state_history_batch_writes_total to be registered")
+
+	var sawOrgLabel bool
+	for _, l := range found.GetLabel() {
+		if l.GetName() == "org" && l.GetValue() == "1" {
+			sawOrgLabel = true
+		}
+	}
+	assert.True(t, sawOrgLabel, "expected ConstLabels org=1 to land on the collector")
+}